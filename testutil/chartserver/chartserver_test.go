@@ -0,0 +1,9 @@
+package chartserver
+
+import "testing"
+
+func TestNewMissingChartDir(t *testing.T) {
+	if _, err := New("testdata/does-not-exist"); err == nil {
+		t.Fatal("expected an error for a chart directory that does not exist")
+	}
+}
@@ -0,0 +1,115 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChartRepoOptions configures authentication and TLS for EnsureChartRepo and
+// for pulling an oci:// chart reference.
+type ChartRepoOptions struct {
+	// Username and Password authenticate against the chart repository. If
+	// left empty, they default to the HELM_REPO_USERNAME and
+	// HELM_REPO_PASSWORD environment variables.
+	Username string
+	Password string
+	// CAFile, CertFile, and KeyFile configure TLS client authentication
+	// against the chart repository.
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// Version selects a specific chart version; if empty, the latest
+	// version is used.
+	Version string
+}
+
+// authArgs renders the --username/--password/--ca-file/--cert-file/--key-file
+// flags shared by `helm repo add` and `helm pull`.
+func (o *ChartRepoOptions) authArgs() []string {
+	if o == nil {
+		return nil
+	}
+
+	username := o.Username
+	if username == "" {
+		username = os.Getenv("HELM_REPO_USERNAME")
+	}
+	password := o.Password
+	if password == "" {
+		password = os.Getenv("HELM_REPO_PASSWORD")
+	}
+
+	var args []string
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	if password != "" {
+		args = append(args, "--password", password)
+	}
+	if o.CAFile != "" {
+		args = append(args, "--ca-file", o.CAFile)
+	}
+	if o.CertFile != "" {
+		args = append(args, "--cert-file", o.CertFile)
+	}
+	if o.KeyFile != "" {
+		args = append(args, "--key-file", o.KeyFile)
+	}
+	return args
+}
+
+// EnsureChartRepo registers a Helm chart repository under the given name via
+// `helm repo add` and refreshes its index via `helm repo update`, so that
+// subsequent HelmInstall/HelmUpgrade calls can reference charts from it.
+func (h *TestHelper) EnsureChartRepo(ctx context.Context, name, url string, opts *ChartRepoOptions) error {
+	args := append([]string{"repo", "add", name, url}, opts.authArgs()...)
+	if out, stderr, err := combinedOutputContext(ctx, "", h.helm.path, args...); err != nil {
+		return fmt.Errorf("failed to add chart repo %q: %w\n%s\n%s", name, err, out, stderr)
+	}
+
+	if out, stderr, err := combinedOutputContext(ctx, "", h.helm.path, "repo", "update", name); err != nil {
+		return fmt.Errorf("failed to update chart repo %q: %w\n%s\n%s", name, err, out, stderr)
+	}
+
+	return nil
+}
+
+// resolveChart prepares chart for use with `helm install`/`helm upgrade`. If
+// chart is an oci:// reference, it is pulled and untarred into a temporary
+// directory, and the path to the untarred chart is returned along with a
+// cleanup function that removes the temporary directory. Otherwise chart is
+// returned unchanged, with a no-op cleanup function.
+func (h *TestHelper) resolveChart(ctx context.Context, chart string, opts *ChartRepoOptions) (string, func(), error) {
+	if !strings.HasPrefix(chart, "oci://") {
+		return chart, func() {}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "linkerd-helm-oci-chart-")
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to create temp dir for oci chart %q: %w", chart, err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	args := []string{"pull", chart, "--untar", "--destination", dir}
+	if opts != nil && opts.Version != "" {
+		args = append(args, "--version", opts.Version)
+	}
+	args = append(args, opts.authArgs()...)
+
+	if out, stderr, err := combinedOutputContext(ctx, "", h.helm.path, args...); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to pull oci chart %q: %w\n%s\n%s", chart, err, out, stderr)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to locate untarred chart for %q in %q", chart, dir)
+	}
+
+	return filepath.Join(dir, entries[0].Name()), cleanup, nil
+}
@@ -0,0 +1,40 @@
+package testutil
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestChartRepoOptionsAuthArgsFromEnv(t *testing.T) {
+	os.Setenv("HELM_REPO_USERNAME", "alice")
+	os.Setenv("HELM_REPO_PASSWORD", "s3cret")
+	defer os.Unsetenv("HELM_REPO_USERNAME")
+	defer os.Unsetenv("HELM_REPO_PASSWORD")
+
+	opts := &ChartRepoOptions{}
+	args := opts.authArgs()
+
+	want := []string{"--username", "alice", "--password", "s3cret"}
+	if len(args) != len(want) {
+		t.Fatalf("expected %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, args)
+		}
+	}
+}
+
+func TestResolveChartPassesThroughLocalPaths(t *testing.T) {
+	h := &TestHelper{helm: helm{path: "helm"}}
+	resolved, cleanup, err := h.resolveChart(context.Background(), "charts/linkerd2", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer cleanup()
+
+	if resolved != "charts/linkerd2" {
+		t.Fatalf("expected chart path to pass through unchanged, got %q", resolved)
+	}
+}
@@ -0,0 +1,201 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// HelmDefaults holds values applied to every release in a HelmState unless
+// the release overrides them.
+type HelmDefaults struct {
+	// Namespace is the namespace releases are installed into when a release
+	// does not specify its own.
+	Namespace string
+	// ValuesFiles is a list of values files applied to every release, before
+	// any release-specific ValuesFiles.
+	ValuesFiles []string
+	// Set is a map of --set overrides applied to every release, before any
+	// release-specific Set overrides.
+	Set map[string]string
+}
+
+// HelmRelease declares a single Helm release to reconcile as part of a
+// HelmState.
+type HelmRelease struct {
+	// Name is the Helm release name.
+	Name string
+	// Chart is the chart reference passed to `helm install`/`helm upgrade`;
+	// it may be a local path, an OCI reference, or a repo/chart reference.
+	Chart string
+	// Version is the chart version to install, passed as `--version`.
+	Version string
+	// Namespace overrides HelmDefaults.Namespace for this release.
+	Namespace string
+	// ValuesFiles is a list of values files passed as `--values`, appended
+	// after HelmDefaults.ValuesFiles.
+	ValuesFiles []string
+	// Set is a map of --set overrides, applied after HelmDefaults.Set.
+	Set map[string]string
+	// Needs lists the names of releases that must be reconciled before this
+	// one.
+	Needs []string
+	// Upgrade indicates that this release should be reconciled with `helm
+	// upgrade` instead of `helm install`, for expressing the "after" half of
+	// an upgrade test.
+	Upgrade bool
+}
+
+// HelmState declares a set of Helm releases to reconcile together, in
+// dependency order, analogous to a helmfile release set.
+type HelmState struct {
+	// Defaults holds values applied to every release unless overridden.
+	Defaults HelmDefaults
+	// Releases is the set of releases to reconcile.
+	Releases []HelmRelease
+}
+
+// ApplyHelmState reconciles every release in state, in dependency order,
+// installing or upgrading each one under its own declared release name.
+func (h *TestHelper) ApplyHelmState(ctx context.Context, state *HelmState) error {
+	order, err := orderReleases(state.Releases)
+	if err != nil {
+		return err
+	}
+
+	for _, release := range order {
+		chart, cleanup, err := h.resolveChart(ctx, release.Chart, &ChartRepoOptions{Version: release.Version})
+		if err != nil {
+			return fmt.Errorf("failed to resolve chart for release %q: %w", release.Name, err)
+		}
+
+		verb := "install"
+		if release.Upgrade {
+			verb = "upgrade"
+		}
+
+		args := append([]string{verb, release.Name, chart, "--kube-context", h.k8sContext}, helmStateArgs(state.Defaults, release)...)
+		out, stderr, err := combinedOutputContext(ctx, "", h.helm.path, args...)
+		cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to reconcile release %q: %w\n%s\n%s", release.Name, err, out, stderr)
+		}
+	}
+
+	return nil
+}
+
+// DestroyHelmState uninstalls every release in state, in reverse dependency
+// order.
+func (h *TestHelper) DestroyHelmState(ctx context.Context, state *HelmState) error {
+	order, err := orderReleases(state.Releases)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for i := len(order) - 1; i >= 0; i-- {
+		release := order[i]
+		namespace := release.Namespace
+		if namespace == "" {
+			namespace = state.Defaults.Namespace
+		}
+
+		args := []string{"uninstall", release.Name, "--kube-context", h.k8sContext}
+		if namespace != "" {
+			args = append(args, "--namespace", namespace)
+		}
+		if _, stderr, err := combinedOutputContext(ctx, "", h.helm.path, args...); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to uninstall release %q: %s\n%s", release.Name, err, stderr))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors destroying helm state:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// helmStateArgs renders the `--version`/`--namespace`/`--values`/`--set`
+// flags for release, layering HelmDefaults ahead of the release's own
+// overrides.
+func helmStateArgs(defaults HelmDefaults, release HelmRelease) []string {
+	var args []string
+
+	if release.Version != "" {
+		args = append(args, "--version", release.Version)
+	}
+
+	namespace := release.Namespace
+	if namespace == "" {
+		namespace = defaults.Namespace
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace, "--create-namespace")
+	}
+
+	for _, f := range defaults.ValuesFiles {
+		args = append(args, "--values", f)
+	}
+	for _, f := range release.ValuesFiles {
+		args = append(args, "--values", f)
+	}
+
+	for k, v := range defaults.Set {
+		args = append(args, "--set", k+"="+v)
+	}
+	for k, v := range release.Set {
+		args = append(args, "--set", k+"="+v)
+	}
+
+	return args
+}
+
+// orderReleases returns releases topologically sorted so that every
+// release appears after the releases named in its Needs.
+func orderReleases(releases []HelmRelease) ([]HelmRelease, error) {
+	byName := make(map[string]HelmRelease, len(releases))
+	for _, r := range releases {
+		byName[r.Name] = r
+	}
+
+	var (
+		ordered []HelmRelease
+		visited = make(map[string]bool)
+		visiting = make(map[string]bool)
+	)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("circular dependency detected involving release %q", name)
+		}
+
+		release, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("release %q is listed as a dependency but not declared", name)
+		}
+
+		visiting[name] = true
+		for _, need := range release.Needs {
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		visited[name] = true
+		ordered = append(ordered, release)
+		return nil
+	}
+
+	for _, r := range releases {
+		if err := visit(r.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
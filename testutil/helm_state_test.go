@@ -0,0 +1,49 @@
+package testutil
+
+import "testing"
+
+func TestOrderReleases(t *testing.T) {
+	releases := []HelmRelease{
+		{Name: "linkerd-multicluster", Needs: []string{"linkerd"}},
+		{Name: "linkerd"},
+		{Name: "sample-app", Needs: []string{"linkerd-multicluster"}},
+	}
+
+	ordered, err := orderReleases(releases)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	index := make(map[string]int, len(ordered))
+	for i, r := range ordered {
+		index[r.Name] = i
+	}
+
+	if index["linkerd"] > index["linkerd-multicluster"] {
+		t.Error("expected linkerd to be ordered before linkerd-multicluster")
+	}
+	if index["linkerd-multicluster"] > index["sample-app"] {
+		t.Error("expected linkerd-multicluster to be ordered before sample-app")
+	}
+}
+
+func TestOrderReleasesCircularDependency(t *testing.T) {
+	releases := []HelmRelease{
+		{Name: "a", Needs: []string{"b"}},
+		{Name: "b", Needs: []string{"a"}},
+	}
+
+	if _, err := orderReleases(releases); err == nil {
+		t.Fatal("expected an error for a circular dependency")
+	}
+}
+
+func TestOrderReleasesMissingDependency(t *testing.T) {
+	releases := []HelmRelease{
+		{Name: "a", Needs: []string{"missing"}},
+	}
+
+	if _, err := orderReleases(releases); err == nil {
+		t.Fatal("expected an error for a missing dependency")
+	}
+}
@@ -0,0 +1,90 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// valueEntry is a single --set/--set-file/--set-string flag, recorded in the
+// order it was added so Render produces deterministic, readable output.
+type valueEntry struct {
+	flag  string
+	key   string
+	value string
+}
+
+// HelmValues builds the --values/--set/--set-file/--set-string flags for a
+// Helm invocation, so tests that need to toggle many chart knobs (TLS
+// issuers, HA replica counts, proxy resources) don't have to hand-splice
+// strings, and so the same set of values can be reused across an
+// install-then-upgrade sequence.
+type HelmValues struct {
+	valuesFiles []string
+	entries     []valueEntry
+}
+
+// Set adds a `--set key=value` flag.
+func (v *HelmValues) Set(key, value string) *HelmValues {
+	v.entries = append(v.entries, valueEntry{flag: "--set", key: key, value: value})
+	return v
+}
+
+// SetFile adds a `--set-file key=path` flag, whose value is read from the
+// file at path at install/upgrade time.
+func (v *HelmValues) SetFile(key, path string) *HelmValues {
+	v.entries = append(v.entries, valueEntry{flag: "--set-file", key: key, value: path})
+	return v
+}
+
+// SetStringJSON adds a `--set-string key=value` flag. A string val is used
+// as-is; any other val is marshaled as JSON first. This is useful for
+// values that helm would otherwise coerce to a non-string type, such as
+// numeric-looking version strings.
+func (v *HelmValues) SetStringJSON(key string, val interface{}) (*HelmValues, error) {
+	s, ok := val.(string)
+	if !ok {
+		b, err := json.Marshal(val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value for key %q: %w", key, err)
+		}
+		s = string(b)
+	}
+	v.entries = append(v.entries, valueEntry{flag: "--set-string", key: key, value: s})
+	return v, nil
+}
+
+// AddValuesFile adds a `--values path` flag.
+func (v *HelmValues) AddValuesFile(path string) *HelmValues {
+	v.valuesFiles = append(v.valuesFiles, path)
+	return v
+}
+
+// Render returns the flags accumulated on v, suitable for passing as the
+// trailing arg... to HelmInstall/HelmUpgrade.
+func (v *HelmValues) Render() []string {
+	if v == nil {
+		return nil
+	}
+
+	var args []string
+	for _, f := range v.valuesFiles {
+		args = append(args, "--values", f)
+	}
+	for _, e := range v.entries {
+		args = append(args, e.flag, e.key+"="+e.value)
+	}
+	return args
+}
+
+// HelmInstallWithValues runs HelmInstall for chart, rendering v ahead of any
+// extra trailing arguments.
+func (h *TestHelper) HelmInstallWithValues(chart string, v *HelmValues, extra ...string) (string, string, error) {
+	return h.HelmInstall(chart, append(v.Render(), extra...)...)
+}
+
+// HelmInstallWithValuesContext is the context-aware variant of
+// HelmInstallWithValues.
+func (h *TestHelper) HelmInstallWithValuesContext(ctx context.Context, chart string, v *HelmValues, extra ...string) (string, string, error) {
+	return h.HelmInstallContext(ctx, chart, append(v.Render(), extra...)...)
+}
@@ -0,0 +1,50 @@
+package testutil
+
+import "testing"
+
+func TestHelmValuesRender(t *testing.T) {
+	v := &HelmValues{}
+	v.AddValuesFile("testdata/values.yaml").
+		Set("global.proxy.resources.cpu.limit", "1").
+		SetFile("identityTrustAnchorsPEM", "testdata/ca.crt")
+	if _, err := v.SetStringJSON("identityTrustDomain", "cluster.local"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{
+		"--values", "testdata/values.yaml",
+		"--set", "global.proxy.resources.cpu.limit=1",
+		"--set-file", "identityTrustAnchorsPEM=testdata/ca.crt",
+		"--set-string", "identityTrustDomain=cluster.local",
+	}
+
+	got := v.Render()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestHelmValuesSetStringJSONNonString(t *testing.T) {
+	v := &HelmValues{}
+	if _, err := v.SetStringJSON("replicas", 3); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"--set-string", "replicas=3"}
+	got := v.Render()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestHelmValuesRenderNil(t *testing.T) {
+	var v *HelmValues
+	if got := v.Render(); got != nil {
+		t.Fatalf("expected nil render for nil *HelmValues, got %v", got)
+	}
+}
@@ -0,0 +1,239 @@
+// Package kindcluster provisions and tears down ephemeral `kind` clusters
+// for the linkerd integration test suite, so operators no longer need to
+// pre-provision a cluster and pass `-k8s-context` to `testutil.NewTestHelper`.
+package kindcluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultNodeImage is used when Config.NodeImage is empty.
+const defaultNodeImage = "kindest/node:v1.21.1"
+
+// Config describes the cluster(s) to provision.
+type Config struct {
+	// Name is the kind cluster name. If empty, "linkerd-integration" is used.
+	Name string
+	// NodeImage is the `kindest/node` image used for every node in the
+	// cluster. If empty, defaultNodeImage is used.
+	NodeImage string
+	// Nodes is the number of worker nodes to create, in addition to the
+	// control-plane node. A value of 0 creates a single-node cluster.
+	Nodes int
+	// KindPath is the path to the kind binary. If empty, "kind" is looked
+	// up on PATH.
+	KindPath string
+	// PodSubnet and ServiceSubnet override the cluster's pod/service CIDRs.
+	// Set these to non-overlapping ranges when linking two clusters for the
+	// multicluster tests, so routes between them are unambiguous.
+	PodSubnet     string
+	ServiceSubnet string
+	// Registry, if set, is a local registry injected into the cluster's
+	// nodes once they're up, so image pulls stay offline.
+	Registry *Registry
+}
+
+// Cluster represents a provisioned kind cluster.
+type Cluster struct {
+	name        string
+	kindPath    string
+	kubeconfig  string
+	kubeContext string
+}
+
+// Create provisions a new kind cluster according to cfg and returns a
+// Cluster that can be used to load images and to tear the cluster down. The
+// returned Cluster's kubeconfig is written to a temporary file; callers are
+// responsible for calling Destroy to remove both the cluster and that file.
+func Create(cfg Config) (*Cluster, error) {
+	kindPath := cfg.KindPath
+	if kindPath == "" {
+		kindPath = "kind"
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = "linkerd-integration"
+	}
+
+	nodeImage := cfg.NodeImage
+	if nodeImage == "" {
+		nodeImage = defaultNodeImage
+	}
+
+	kubeconfig, err := ioutil.TempFile("", fmt.Sprintf("kind-%s-kubeconfig-", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubeconfig temp file: %w", err)
+	}
+	kubeconfig.Close()
+
+	c := &Cluster{
+		name:        name,
+		kindPath:    kindPath,
+		kubeconfig:  kubeconfig.Name(),
+		kubeContext: "kind-" + name,
+	}
+
+	args := []string{
+		"create", "cluster",
+		"--name", name,
+		"--image", nodeImage,
+		"--kubeconfig", c.kubeconfig,
+	}
+
+	if cfg.Nodes > 0 || cfg.PodSubnet != "" || cfg.ServiceSubnet != "" {
+		kindConfig, err := writeKindConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(kindConfig)
+		args = append(args, "--config", kindConfig)
+	}
+
+	if out, err := exec.Command(kindPath, args...).CombinedOutput(); err != nil {
+		os.Remove(c.kubeconfig)
+		return nil, fmt.Errorf("failed to create kind cluster %q: %w\n%s", name, err, out)
+	}
+
+	if cfg.Registry != nil {
+		if err := cfg.Registry.Connect(c); err != nil {
+			c.Destroy()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// CreateLinkedPair provisions two kind clusters, a and b, for use in the
+// multicluster tests. kind places every cluster on the same "kind" Docker
+// network by default, so the two clusters can already reach each other;
+// CreateLinkedPair additionally assigns them non-overlapping pod/service
+// CIDRs (if not already set on the given configs) so that routes between
+// the two are unambiguous once they're linked via `linkerd multicluster
+// link`.
+func CreateLinkedPair(a, b Config) (*Cluster, *Cluster, error) {
+	a, b = defaultLinkedPairConfigs(a, b)
+
+	clusterA, err := Create(a)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create first cluster of linked pair: %w", err)
+	}
+
+	clusterB, err := Create(b)
+	if err != nil {
+		clusterA.Destroy()
+		return nil, nil, fmt.Errorf("failed to create second cluster of linked pair: %w", err)
+	}
+
+	return clusterA, clusterB, nil
+}
+
+// defaultLinkedPairConfigs fills in distinct default names and
+// non-overlapping pod/service CIDRs for a and b, for any field the caller
+// left unset.
+func defaultLinkedPairConfigs(a, b Config) (Config, Config) {
+	if a.Name == "" {
+		a.Name = "linkerd-integration-source"
+	}
+	if b.Name == "" {
+		b.Name = "linkerd-integration-target"
+	}
+
+	if a.PodSubnet == "" {
+		a.PodSubnet = "10.244.0.0/16"
+	}
+	if a.ServiceSubnet == "" {
+		a.ServiceSubnet = "10.96.0.0/16"
+	}
+	if b.PodSubnet == "" {
+		b.PodSubnet = "10.245.0.0/16"
+	}
+	if b.ServiceSubnet == "" {
+		b.ServiceSubnet = "10.97.0.0/16"
+	}
+
+	return a, b
+}
+
+// writeKindConfig writes a kind cluster config requesting one control-plane
+// node, the given number of worker nodes, and the pod/service CIDRs in cfg,
+// and returns its path.
+func writeKindConfig(cfg Config) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n")
+
+	if cfg.PodSubnet != "" || cfg.ServiceSubnet != "" {
+		sb.WriteString("networking:\n")
+		if cfg.PodSubnet != "" {
+			fmt.Fprintf(&sb, "  podSubnet: %s\n", cfg.PodSubnet)
+		}
+		if cfg.ServiceSubnet != "" {
+			fmt.Fprintf(&sb, "  serviceSubnet: %s\n", cfg.ServiceSubnet)
+		}
+	}
+
+	sb.WriteString("nodes:\n- role: control-plane\n")
+	for i := 0; i < cfg.Nodes; i++ {
+		sb.WriteString("- role: worker\n")
+	}
+
+	f, err := ioutil.TempFile("", "kind-config-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create kind config temp file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return "", fmt.Errorf("failed to write kind config: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// Name returns the kind cluster's name.
+func (c *Cluster) Name() string {
+	return c.name
+}
+
+// Kubeconfig returns the path to the kubeconfig file generated for this
+// cluster.
+func (c *Cluster) Kubeconfig() string {
+	return c.kubeconfig
+}
+
+// KubeContext returns the kubeconfig context name for this cluster, of the
+// form "kind-<name>".
+func (c *Cluster) KubeContext() string {
+	return c.kubeContext
+}
+
+// LoadImages loads the given locally built image references into every node
+// of the cluster via `kind load docker-image`, so that pods can pull them
+// without reaching out to a registry.
+func (c *Cluster) LoadImages(images ...string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	args := append([]string{"load", "docker-image", "--name", c.name}, images...)
+	if out, err := exec.Command(c.kindPath, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load images into kind cluster %q: %w\n%s", c.name, err, out)
+	}
+	return nil
+}
+
+// Destroy deletes the kind cluster and removes its kubeconfig file.
+func (c *Cluster) Destroy() error {
+	defer os.Remove(c.kubeconfig)
+
+	out, err := exec.Command(c.kindPath, "delete", "cluster", "--name", c.name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete kind cluster %q: %w\n%s", c.name, err, out)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package kindcluster
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCreateMissingKindBinary(t *testing.T) {
+	if _, err := Create(Config{KindPath: "/nonexistent-kind-binary"}); err == nil {
+		t.Fatal("expected an error when the kind binary cannot be found")
+	}
+}
+
+func TestDefaultLinkedPairConfigsAssignsDistinctNames(t *testing.T) {
+	a, b := defaultLinkedPairConfigs(Config{}, Config{})
+
+	if a.Name == "" || b.Name == "" {
+		t.Fatal("expected both configs to get a default name")
+	}
+	if a.Name == b.Name {
+		t.Fatalf("expected distinct default names, got %q for both", a.Name)
+	}
+	if a.PodSubnet == b.PodSubnet || a.ServiceSubnet == b.ServiceSubnet {
+		t.Fatal("expected non-overlapping default pod/service subnets")
+	}
+}
+
+func TestDefaultLinkedPairConfigsPreservesCallerNames(t *testing.T) {
+	a, b := defaultLinkedPairConfigs(Config{Name: "east"}, Config{Name: "west"})
+
+	if a.Name != "east" || b.Name != "west" {
+		t.Fatalf("expected caller-provided names to be preserved, got %q and %q", a.Name, b.Name)
+	}
+}
+
+func TestWriteKindConfig(t *testing.T) {
+	path, err := writeKindConfig(Config{Nodes: 2, PodSubnet: "10.244.0.0/16", ServiceSubnet: "10.96.0.0/16"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.Remove(path)
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading config: %s", err)
+	}
+	if got := string(contents); got == "" {
+		t.Fatal("expected a non-empty kind config")
+	}
+}
@@ -0,0 +1,108 @@
+package kindcluster
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// defaultRegistryName and defaultRegistryPort are used when RegistryConfig
+// leaves the corresponding field empty.
+const (
+	defaultRegistryName = "kind-registry"
+	defaultRegistryPort = 5000
+)
+
+// RegistryConfig configures a local image registry to inject into one or
+// more kind clusters.
+type RegistryConfig struct {
+	// Name is the name of the registry's Docker container. If empty,
+	// defaultRegistryName is used.
+	Name string
+	// Port is the host port the registry is published on. If empty,
+	// defaultRegistryPort is used.
+	Port int
+}
+
+// Registry is a local, in-Docker image registry that can be connected to one
+// or more kind clusters so that image pulls stay offline, following the
+// pattern documented at https://kind.sigs.k8s.io/docs/user/local-registry/.
+type Registry struct {
+	name string
+	port int
+}
+
+// CreateRegistry starts a local registry container according to cfg, or
+// reuses one that's already running under the same name.
+func CreateRegistry(cfg RegistryConfig) (*Registry, error) {
+	name := cfg.Name
+	if name == "" {
+		name = defaultRegistryName
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultRegistryPort
+	}
+
+	r := &Registry{name: name, port: port}
+
+	running, err := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", name).CombinedOutput()
+	if err == nil && strings.TrimSpace(string(running)) == "true" {
+		return r, nil
+	}
+
+	args := []string{
+		"run", "-d", "--restart=always",
+		"-p", fmt.Sprintf("127.0.0.1:%d:5000", port),
+		"--name", name,
+		"registry:2",
+	}
+	if out, err := exec.Command("docker", args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to start local registry %q: %w\n%s", name, err, out)
+	}
+
+	return r, nil
+}
+
+// URL returns the address pods should use to pull images from the registry,
+// of the form "localhost:<port>".
+func (r *Registry) URL() string {
+	return fmt.Sprintf("localhost:%d", r.port)
+}
+
+// Connect attaches the registry to c's Docker network and configures every
+// node in c to resolve "localhost:<port>" image references to it, so pods
+// scheduled on c can pull images from the registry without reaching out to
+// an external network.
+func (r *Registry) Connect(c *Cluster) error {
+	if out, err := exec.Command("docker", "network", "connect", "kind", r.name).CombinedOutput(); err != nil &&
+		!strings.Contains(string(out), "already exists in network") {
+		return fmt.Errorf("failed to connect registry %q to the kind network: %w\n%s", r.name, err, out)
+	}
+
+	nodesOut, err := exec.Command(c.kindPath, "get", "nodes", "--name", c.name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of cluster %q: %w\n%s", c.name, err, nodesOut)
+	}
+
+	hostsToml := fmt.Sprintf("[host.\"http://%s:5000\"]\n", r.name)
+	configDir := fmt.Sprintf("/etc/containerd/certs.d/localhost:%d", r.port)
+	script := fmt.Sprintf("mkdir -p %s && printf '%s' > %s/hosts.toml", configDir, hostsToml, configDir)
+
+	for _, node := range strings.Fields(string(nodesOut)) {
+		if out, err := exec.Command("docker", "exec", node, "sh", "-c", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to configure registry mirror on node %q: %w\n%s", node, err, out)
+		}
+	}
+
+	return nil
+}
+
+// Destroy removes the registry's Docker container.
+func (r *Registry) Destroy() error {
+	if out, err := exec.Command("docker", "rm", "-f", r.name).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove local registry %q: %w\n%s", r.name, err, out)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,13 +12,18 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/linkerd/linkerd2/testutil/chartserver"
+	"github.com/linkerd/linkerd2/testutil/kindcluster"
 )
 
 // TestHelper provides helpers for running the linkerd integration tests.
@@ -31,6 +37,12 @@ type TestHelper struct {
 	multicluster       bool
 	uninstall          bool
 	httpClient         http.Client
+	cmdTimeout         time.Duration
+	kindCluster        *kindcluster.Cluster
+	kindTargetCluster  *kindcluster.Cluster
+	kindRegistry       *kindcluster.Registry
+	chartServer        *chartserver.Server
+	chartRepoOpts      *ChartRepoOptions
 	KubernetesHelper
 	helm
 }
@@ -43,6 +55,10 @@ type helm struct {
 	releaseName             string
 	multiclusterReleaseName string
 	upgradeFromVersion      string
+	ociChart                string
+	ociChartVersion         string
+	repoName                string
+	repoURL                 string
 }
 
 // DeploySpec is used to hold information about what deploys we should verify during testing
@@ -142,6 +158,16 @@ func NewTestHelper() *TestHelper {
 	verbose := flag.Bool("verbose", false, "turn on debug logging")
 	upgradeHelmFromVersion := flag.String("upgrade-helm-from-version", "", "Indicate a version of the Linkerd helm chart from which the helm installation is being upgraded")
 	uninstall := flag.Bool("uninstall", false, "whether to run the 'linkerd uninstall' integration test")
+	cmdTimeout := flag.Duration("cmd-timeout", 5*time.Minute, "timeout for each linkerd/helm subprocess started by LinkerdRun, PipeToLinkerdRun, HelmInstall or HelmUpgrade")
+	provisionCluster := flag.String("provision-cluster", "", "provision a test cluster rather than using -k8s-context; supported values: \"kind\"")
+	kindNodeImage := flag.String("kind-node-image", "", "the kindest/node image to use when -provision-cluster=kind")
+	kindWorkerNodes := flag.Int("kind-worker-nodes", 0, "the number of additional worker nodes to create when -provision-cluster=kind")
+	kindLocalRegistry := flag.Bool("kind-local-registry", false, "inject a local image registry into the kind cluster(s) so image pulls stay offline, when -provision-cluster=kind")
+	kindLocalRegistryPort := flag.Int("kind-local-registry-port", 0, "the host port to publish the -kind-local-registry on; defaults to 5000")
+	helmOCIChart := flag.String("helm-oci-chart", "", "an oci:// reference to the linkerd2 Helm chart, returned by GetHelmOCIChart; GetHelmChart still returns -helm-chart")
+	helmOCIChartVersion := flag.String("helm-oci-chart-version", "", "the chart version to select when pulling an oci:// chart reference")
+	helmRepoURL := flag.String("helm-repo-url", "", "the URL of a Helm chart repository, returned by GetHelmRepoURL for use with EnsureChartRepo; it is not added automatically")
+	helmRepoName := flag.String("helm-repo-name", "", "the local name to register the -helm-repo-url chart repository under")
 	flag.Parse()
 
 	if !*runTests {
@@ -180,10 +206,15 @@ func NewTestHelper() *TestHelper {
 			releaseName:             *helmReleaseName,
 			multiclusterReleaseName: *multiclusterHelmReleaseName,
 			upgradeFromVersion:      *upgradeHelmFromVersion,
+			ociChart:                *helmOCIChart,
+			ociChartVersion:         *helmOCIChartVersion,
+			repoName:                *helmRepoName,
+			repoURL:                 *helmRepoURL,
 		},
 		clusterDomain:  *clusterDomain,
 		externalIssuer: *externalIssuer,
 		uninstall:      *uninstall,
+		cmdTimeout:     *cmdTimeout,
 	}
 
 	version, stderr, err := testHelper.LinkerdRun("version", "--client", "--short")
@@ -192,7 +223,53 @@ func NewTestHelper() *TestHelper {
 	}
 	testHelper.version = strings.TrimSpace(version)
 
-	kubernetesHelper, err := NewKubernetesHelper(*k8sContext, testHelper.RetryFor)
+	contextOverride := *k8sContext
+	if *provisionCluster != "" {
+		if *provisionCluster != "kind" {
+			exit(1, fmt.Sprintf("unsupported -provision-cluster value %q: only \"kind\" is supported", *provisionCluster))
+		}
+
+		var registry *kindcluster.Registry
+		if *kindLocalRegistry {
+			registry, err = kindcluster.CreateRegistry(kindcluster.RegistryConfig{Port: *kindLocalRegistryPort})
+			if err != nil {
+				exit(1, fmt.Sprintf("error starting local kind registry: %s", err.Error()))
+			}
+			testHelper.kindRegistry = registry
+		}
+
+		cfg := kindcluster.Config{
+			NodeImage: *kindNodeImage,
+			Nodes:     *kindWorkerNodes,
+			Registry:  registry,
+		}
+
+		kubeconfigs := []string{}
+		if *multicluster {
+			source, target, err := kindcluster.CreateLinkedPair(cfg, cfg)
+			if err != nil {
+				exit(1, fmt.Sprintf("error provisioning linked kind clusters: %s", err.Error()))
+			}
+			testHelper.kindCluster = source
+			testHelper.kindTargetCluster = target
+			contextOverride = source.KubeContext()
+			kubeconfigs = []string{source.Kubeconfig(), target.Kubeconfig()}
+		} else {
+			cluster, err := kindcluster.Create(cfg)
+			if err != nil {
+				exit(1, fmt.Sprintf("error provisioning kind cluster: %s", err.Error()))
+			}
+			testHelper.kindCluster = cluster
+			contextOverride = cluster.KubeContext()
+			kubeconfigs = []string{cluster.Kubeconfig()}
+		}
+
+		if err := os.Setenv("KUBECONFIG", strings.Join(kubeconfigs, string(os.PathListSeparator))); err != nil {
+			exit(1, fmt.Sprintf("error setting KUBECONFIG: %s", err.Error()))
+		}
+	}
+
+	kubernetesHelper, err := NewKubernetesHelper(contextOverride, testHelper.RetryFor)
 	if err != nil {
 		exit(1, fmt.Sprintf("error creating kubernetes helper: %s\n%s", err.Error(), stderr))
 	}
@@ -255,6 +332,41 @@ func (h *TestHelper) GetHelmStableChart() string {
 	return h.helm.stableChart
 }
 
+// GetHelmOCIChart returns the oci:// chart reference configured via the
+// -helm-oci-chart flag, if any.
+func (h *TestHelper) GetHelmOCIChart() string {
+	return h.helm.ociChart
+}
+
+// GetHelmRepoURL returns the Helm chart repository URL configured via the
+// -helm-repo-url flag, if any.
+func (h *TestHelper) GetHelmRepoURL() string {
+	return h.helm.repoURL
+}
+
+// GetHelmRepoName returns the local name the -helm-repo-url repository is
+// registered under, configured via the -helm-repo-name flag.
+func (h *TestHelper) GetHelmRepoName() string {
+	return h.helm.repoName
+}
+
+// SetChartRepoOptions configures the auth/version options HelmInstall and
+// HelmUpgrade use when pulling an oci:// chart reference. Pass nil to revert
+// to the defaults derived from the -helm-oci-chart-version flag and the
+// HELM_REPO_USERNAME/HELM_REPO_PASSWORD environment variables.
+func (h *TestHelper) SetChartRepoOptions(opts *ChartRepoOptions) {
+	h.chartRepoOpts = opts
+}
+
+// chartRepoOptions returns the ChartRepoOptions HelmInstall and HelmUpgrade
+// should use to resolve an oci:// chart reference.
+func (h *TestHelper) chartRepoOptions() *ChartRepoOptions {
+	if h.chartRepoOpts != nil {
+		return h.chartRepoOpts
+	}
+	return &ChartRepoOptions{Version: h.helm.ociChartVersion}
+}
+
 // UpgradeHelmFromVersion returns the version from which Linkerd should be upgraded with Helm
 func (h *TestHelper) UpgradeHelmFromVersion() string {
 	return h.helm.upgradeFromVersion
@@ -285,6 +397,57 @@ func (h *TestHelper) GetClusterDomain() string {
 	return h.clusterDomain
 }
 
+// GetCmdTimeout returns the timeout applied to each linkerd/helm subprocess
+// started by LinkerdRun, PipeToLinkerdRun, HelmInstall or HelmUpgrade,
+// configured via the -cmd-timeout command line flag.
+func (h *TestHelper) GetCmdTimeout() time.Duration {
+	return h.cmdTimeout
+}
+
+// ChartServerURL lazily starts an in-process Helm chart repository serving
+// the linkerd2 and linkerd2-multicluster charts configured on h, and
+// returns its base URL. The repository is stopped when Run tears down the
+// test binary. Subsequent calls return the same URL without restarting the
+// server.
+func (h *TestHelper) ChartServerURL() (string, error) {
+	if h.chartServer == nil {
+		srv, err := chartserver.New(h.helm.chart, h.helm.multiclusterChart)
+		if err != nil {
+			return "", fmt.Errorf("failed to start chart server: %w", err)
+		}
+		h.chartServer = srv
+	}
+	return h.chartServer.URL(), nil
+}
+
+// LoadDockerImages loads the given locally built image references into the
+// provisioned kind cluster's nodes, so pods can pull them without reaching a
+// registry. It is a no-op if the test is not running against a kind cluster
+// provisioned via -provision-cluster=kind.
+func (h *TestHelper) LoadDockerImages(images ...string) error {
+	if h.kindCluster == nil {
+		return nil
+	}
+	if err := h.kindCluster.LoadImages(images...); err != nil {
+		return err
+	}
+	if h.kindTargetCluster != nil {
+		return h.kindTargetCluster.LoadImages(images...)
+	}
+	return nil
+}
+
+// GetMulticlusterKubeContext returns the kubeconfig context of the second
+// kind cluster provisioned for the multicluster tests when running with
+// -provision-cluster=kind -multicluster. It returns an empty string if no
+// second cluster was provisioned.
+func (h *TestHelper) GetMulticlusterKubeContext() string {
+	if h.kindTargetCluster == nil {
+		return ""
+	}
+	return h.kindTargetCluster.KubeContext()
+}
+
 // CreateTLSSecret creates a TLS Kubernetes secret
 func (h *TestHelper) CreateTLSSecret(name, root, cert, key string) error {
 	secret := fmt.Sprintf(`
@@ -308,19 +471,43 @@ func (h *TestHelper) LinkerdRun(arg ...string) (string, string, error) {
 	return h.PipeToLinkerdRun("", arg...)
 }
 
+// LinkerdRunContext executes a linkerd command appended with the
+// --linkerd-namespace flag, aborting it if ctx is canceled before it
+// completes.
+func (h *TestHelper) LinkerdRunContext(ctx context.Context, arg ...string) (string, string, error) {
+	return h.PipeToLinkerdRunContext(ctx, "", arg...)
+}
+
 // PipeToLinkerdRun executes a linkerd command appended with the
 // --linkerd-namespace flag, and provides a string at Stdin.
 func (h *TestHelper) PipeToLinkerdRun(stdin string, arg ...string) (string, string, error) {
+	ctx, cancel := h.withCmdTimeout(RootContext())
+	defer cancel()
+	return h.PipeToLinkerdRunContext(ctx, stdin, arg...)
+}
+
+// PipeToLinkerdRunContext executes a linkerd command appended with the
+// --linkerd-namespace flag, provides a string at Stdin, and aborts the
+// command if ctx is canceled before it completes.
+func (h *TestHelper) PipeToLinkerdRunContext(ctx context.Context, stdin string, arg ...string) (string, string, error) {
 	withParams := append([]string{"--linkerd-namespace", h.namespace, "--context=" + h.k8sContext}, arg...)
-	return combinedOutput(stdin, h.linkerd, withParams...)
+	return combinedOutputContext(ctx, stdin, h.linkerd, withParams...)
 }
 
 // LinkerdRunStream initiates a linkerd command appended with the
 // --linkerd-namespace flag, and returns a Stream that can be used to read the
 // command's output while it is still executing.
 func (h *TestHelper) LinkerdRunStream(arg ...string) (*Stream, error) {
+	return h.LinkerdRunStreamContext(RootContext(), arg...)
+}
+
+// LinkerdRunStreamContext initiates a linkerd command appended with the
+// --linkerd-namespace flag, and returns a Stream that can be used to read the
+// command's output while it is still executing. The underlying process is
+// killed if ctx is canceled before the Stream is closed.
+func (h *TestHelper) LinkerdRunStreamContext(ctx context.Context, arg ...string) (*Stream, error) {
 	withParams := append([]string{"--linkerd-namespace", h.namespace, "--context=" + h.k8sContext}, arg...)
-	cmd := exec.Command(h.linkerd, withParams...)
+	cmd := exec.CommandContext(ctx, h.linkerd, withParams...)
 
 	cmdReader, err := cmd.StdoutPipe()
 	if err != nil {
@@ -343,26 +530,66 @@ func (h *TestHelper) LinkerdRunStream(arg ...string) (*Stream, error) {
 
 // HelmUpgrade runs the helm upgrade subcommand, with the provided arguments
 func (h *TestHelper) HelmUpgrade(chart string, arg ...string) (string, string, error) {
+	ctx, cancel := h.withCmdTimeout(RootContext())
+	defer cancel()
+	return h.HelmUpgradeContext(ctx, chart, arg...)
+}
+
+// HelmUpgradeContext runs the helm upgrade subcommand, with the provided
+// arguments, aborting it if ctx is canceled before it completes. If chart is
+// an oci:// reference, it is pulled to a local directory first.
+func (h *TestHelper) HelmUpgradeContext(ctx context.Context, chart string, arg ...string) (string, string, error) {
+	resolved, cleanup, err := h.resolveChart(ctx, chart, h.chartRepoOptions())
+	if err != nil {
+		return "", "", err
+	}
+	defer cleanup()
+
 	withParams := append([]string{
 		"upgrade",
 		h.helm.releaseName,
 		"--kube-context", h.k8sContext,
 		"--set", "global.namespace=" + h.namespace,
-		chart,
+		resolved,
 	}, arg...)
-	return combinedOutput("", h.helm.path, withParams...)
+	return combinedOutputContext(ctx, "", h.helm.path, withParams...)
 }
 
 // HelmInstall runs the helm install subcommand, with the provided arguments
 func (h *TestHelper) HelmInstall(chart string, arg ...string) (string, string, error) {
+	ctx, cancel := h.withCmdTimeout(RootContext())
+	defer cancel()
+	return h.HelmInstallContext(ctx, chart, arg...)
+}
+
+// HelmInstallContext runs the helm install subcommand, with the provided
+// arguments, aborting it if ctx is canceled before it completes. If chart is
+// an oci:// reference, it is pulled to a local directory first, so that
+// downstream code paths that expect a local chart path keep working.
+func (h *TestHelper) HelmInstallContext(ctx context.Context, chart string, arg ...string) (string, string, error) {
+	resolved, cleanup, err := h.resolveChart(ctx, chart, h.chartRepoOptions())
+	if err != nil {
+		return "", "", err
+	}
+	defer cleanup()
+
 	withParams := append([]string{
 		"install",
 		h.helm.releaseName,
-		chart,
+		resolved,
 		"--kube-context", h.k8sContext,
 		"--set", "global.namespace=" + h.namespace,
 	}, arg...)
-	return combinedOutput("", h.helm.path, withParams...)
+	return combinedOutputContext(ctx, "", h.helm.path, withParams...)
+}
+
+// withCmdTimeout returns a child of ctx that is canceled after the
+// TestHelper's configured command timeout, if one is set.
+func (h *TestHelper) withCmdTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.cmdTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.cmdTimeout)
 }
 
 // HelmInstallMulticluster runs the helm install subcommand for multicluster, with the provided arguments
@@ -474,7 +701,13 @@ func ReadFile(file string) (string, error) {
 
 // combinedOutput executes a shell command and returns the output.
 func combinedOutput(stdin string, name string, arg ...string) (string, string, error) {
-	command := exec.Command(name, arg...)
+	return combinedOutputContext(context.Background(), stdin, name, arg...)
+}
+
+// combinedOutputContext executes a shell command and returns the output,
+// killing the subprocess if ctx is canceled before it completes.
+func combinedOutputContext(ctx context.Context, stdin string, name string, arg ...string) (string, string, error) {
+	command := exec.CommandContext(ctx, name, arg...)
 	command.Stdin = strings.NewReader(stdin)
 	var stderr bytes.Buffer
 	command.Stderr = &stderr
@@ -580,11 +813,79 @@ func ParseEvents(out string) ([]*corev1.Event, error) {
 	return events, nil
 }
 
+// rootCtx is canceled by Run when it receives SIGINT or SIGTERM, so that
+// tests can thread it into the ...Context helpers on TestHelper to abort
+// in-flight subprocesses on early termination.
+var rootCtx = context.Background()
+
+// RootContext returns the context installed by Run. It is canceled when the
+// test binary receives SIGINT or SIGTERM, so long-running tests should pass
+// it to the ...Context variants of TestHelper's command helpers.
+func RootContext() context.Context {
+	return rootCtx
+}
+
 // Run calls `m.Run()`, shows unexpected logs/events,
-// and returns the exit code for the tests
+// and returns the exit code for the tests. It installs a signal handler for
+// SIGINT and SIGTERM that cancels a root context, aborting any in-flight
+// helm/linkerd subprocesses started by TestHelper's LinkerdRun, HelmInstall
+// and HelmUpgrade helpers, or by their ...Context variants given
+// RootContext(). kubectl commands run by the embedded KubernetesHelper do
+// not use this context and are not aborted.
 func Run(m *testing.M, helper *TestHelper) int {
+	ctx, cancel := context.WithCancel(context.Background())
+	rootCtx = ctx
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-sigCh
+		if !ok {
+			return
+		}
+		fmt.Printf("received %s, canceling in-flight commands\n", sig)
+		cancel()
+	}()
+	defer close(sigCh)
+	defer signal.Stop(sigCh)
+
+	if helper.kindCluster != nil {
+		defer func() {
+			if err := helper.kindCluster.Destroy(); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+
+	if helper.kindTargetCluster != nil {
+		defer func() {
+			if err := helper.kindTargetCluster.Destroy(); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+
+	if helper.kindRegistry != nil {
+		defer func() {
+			if err := helper.kindRegistry.Destroy(); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+
+	// chartServer is started lazily by ChartServerURL while the tests run,
+	// so it is checked when the deferred call runs rather than here.
+	defer func() {
+		if helper.chartServer != nil {
+			if err := helper.chartServer.Close(); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}()
+
 	code := m.Run()
-	if code != 0 {
+	if code != 0 || ctx.Err() != nil {
 		_, errs1 := FetchAndCheckLogs(helper)
 		for _, err := range errs1 {
 			fmt.Println(err)